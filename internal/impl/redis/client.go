@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"crypto/tls"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/benthosdev/benthos/v4/internal/impl/redis/old"
+)
+
+// clientFromConfig returns a redis.UniversalClient configured from conf. The
+// kind field selects between a simple, cluster-aware, or sentinel-aware
+// (failover) client, all of which satisfy the same UniversalClient
+// interface used by callers.
+//
+// redis_streams is currently the only component in this package, and
+// output_streams.go is its only caller; there are no other v7 call sites
+// left to migrate.
+func clientFromConfig(conf old.Config) (redis.UniversalClient, error) {
+	parsed, err := redis.ParseURL(conf.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var tlsConf *tls.Config
+	if conf.TLS.Enabled {
+		if tlsConf, err = conf.TLS.Get(); err != nil {
+			return nil, err
+		}
+	}
+
+	uOpts := &redis.UniversalOptions{
+		Addrs:      []string{parsed.Addr},
+		DB:         parsed.DB,
+		Username:   parsed.Username,
+		Password:   parsed.Password,
+		MasterName: conf.MasterName,
+		TLSConfig:  tlsConf,
+	}
+
+	switch strings.ToLower(conf.Kind) {
+	case "cluster":
+		return redis.NewClusterClient(uOpts.Cluster()), nil
+	case "failover":
+		return redis.NewFailoverClient(uOpts.Failover()), nil
+	default:
+		return redis.NewClient(uOpts.Simple()), nil
+	}
+}
@@ -0,0 +1,36 @@
+// Package old contains the legacy connection config used by the components
+// of the redis impl package (currently only the redis_streams output).
+package old
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	btls "github.com/benthosdev/benthos/v4/internal/tls"
+)
+
+// Config contains the fields common to any component that connects to a
+// Redis instance, cluster, or sentinel-backed failover group.
+type Config struct {
+	URL        string      `json:"url" yaml:"url"`
+	Kind       string      `json:"kind" yaml:"kind"`
+	MasterName string      `json:"master_name" yaml:"master_name"`
+	TLS        btls.Config `json:"tls" yaml:"tls"`
+}
+
+// NewConfig returns a Config with default values.
+func NewConfig() Config {
+	return Config{
+		URL:  "redis://localhost:6379",
+		Kind: "simple",
+		TLS:  btls.NewConfig(),
+	}
+}
+
+// ConfigDocs returns a documentation spec for the fields of a Config.
+func ConfigDocs() docs.FieldSpecs {
+	return docs.FieldSpecs{
+		docs.FieldString("url", "The URL of the target Redis server. Database is optional and is supplied as the URL path."),
+		docs.FieldString("kind", "Specifies a simple, cluster-aware, or failover-aware redis client.").HasOptions("simple", "cluster", "failover").Advanced(),
+		docs.FieldString("master_name", "Name of the redis master when `kind` is `failover`").Advanced(),
+		docs.FieldObject("tls", "Custom TLS settings can be used to override system defaults.").WithChildren(btls.FieldSpec()...).Advanced(),
+	}
+}
@@ -2,10 +2,12 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 
-	"github.com/go-redis/redis/v7"
+	"github.com/go-redis/redis/v8"
 
 	ibatch "github.com/benthosdev/benthos/v4/internal/batch"
 	"github.com/benthosdev/benthos/v4/internal/batch/policy"
@@ -40,7 +42,15 @@ a metadata item and the body then the body takes precedence.`),
 		Config: docs.FieldComponent().WithChildren(old.ConfigDocs()...).WithChildren(
 			docs.FieldString("stream", "The stream to add messages to.").IsInterpolated(),
 			docs.FieldString("body_key", "A key to set the raw body of the message to."),
-			docs.FieldInt("max_length", "When greater than zero enforces a rough cap on the length of the target stream."),
+			docs.FieldString("id", "The ID to assign to added messages, specify `*` to allow Redis to automatically generate a unique sequential ID. Can be set to a deterministic value in order to implement idempotent producers, in which case a non-monotonic ID will be rejected by Redis and surfaced as a batch error.").IsInterpolated().Advanced().HasDefault("*"),
+			docs.FieldBool("no_mk_stream", "When set to `true` prevents the target stream from being automatically created when it does not already exist, causing writes to a missing stream to fail instead.").Advanced().HasDefault(false),
+			docs.FieldInt("max_length", "When greater than zero enforces a rough cap on the length of the target stream.").Deprecated(),
+			docs.FieldObject("trim", "Allows you to specify a trimming strategy for the target stream, overriding `max_length`.").WithChildren(
+				docs.FieldString("strategy", "The trimming strategy to apply.").HasOptions("maxlen", "minid"),
+				docs.FieldString("mode", "Whether the trim should be exact or approximate. Approximate trimming is more efficient as Redis only removes whole macro nodes.").HasOptions("approx", "exact"),
+				docs.FieldString("value", "For the `maxlen` strategy this is the maximum length of the target stream. For the `minid` strategy this is the minimum ID to retain, and may be interpolated, e.g. to express a rolling time window as a millisecond timestamp.").IsInterpolated(),
+				docs.FieldInt("limit", "An optional cap on the number of entries Redis will evict per call when trimming approximately.").Advanced().Optional(),
+			).Advanced().Optional(),
 			docs.FieldInt("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
 			docs.FieldObject("metadata", "Specify criteria for which metadata values are included in the message body.").WithChildren(metadata.ExcludeFilterFields()...),
 			policy.FieldSpec(),
@@ -71,6 +81,8 @@ type redisStreamsWriter struct {
 
 	conf       output.RedisStreamsConfig
 	stream     *field.Expression
+	id         *field.Expression
+	trimValue  *field.Expression
 	metaFilter *metadata.ExcludeFilter
 
 	client  redis.UniversalClient
@@ -87,9 +99,17 @@ func newRedisStreamsWriter(conf output.RedisStreamsConfig, mgr bundle.NewManagem
 	if r.stream, err = mgr.BloblEnvironment().NewField(conf.Stream); err != nil {
 		return nil, fmt.Errorf("failed to parse expression: %v", err)
 	}
+	if r.id, err = mgr.BloblEnvironment().NewField(conf.ID); err != nil {
+		return nil, fmt.Errorf("failed to parse id expression: %v", err)
+	}
 	if r.metaFilter, err = conf.Metadata.Filter(); err != nil {
 		return nil, fmt.Errorf("failed to construct metadata filter: %w", err)
 	}
+	if conf.Trim.Strategy != "" {
+		if r.trimValue, err = mgr.BloblEnvironment().NewField(conf.Trim.Value); err != nil {
+			return nil, fmt.Errorf("failed to parse trim value expression: %v", err)
+		}
+	}
 
 	if _, err = clientFromConfig(conf.Config); err != nil {
 		return nil, err
@@ -105,7 +125,7 @@ func (r *redisStreamsWriter) Connect(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	if _, err = client.Ping().Result(); err != nil {
+	if _, err = client.Ping(ctx).Result(); err != nil {
 		return err
 	}
 
@@ -115,6 +135,55 @@ func (r *redisStreamsWriter) Connect(ctx context.Context) error {
 	return nil
 }
 
+// applyTrim populates the trimming fields of args for part i of msg,
+// according to the configured trim strategy. When no strategy is set the
+// legacy max_length field is used for backwards compatibility.
+func (r *redisStreamsWriter) applyTrim(i int, msg message.Batch, args *redis.XAddArgs) {
+	exact := r.conf.Trim.Mode == "exact"
+
+	switch r.conf.Trim.Strategy {
+	case "maxlen":
+		length, err := strconv.ParseInt(r.trimValue.String(i, msg), 10, 64)
+		if err != nil {
+			r.log.Errorf("Failed to parse trim value '%v' as an integer, skipping trim: %v\n", r.trimValue.String(i, msg), err)
+			return
+		}
+		args.MaxLen = length
+		args.Approx = !exact
+	case "minid":
+		args.MinID = r.trimValue.String(i, msg)
+		args.Approx = !exact
+	default:
+		args.MaxLen = r.conf.MaxLenApprox
+		args.Approx = true
+		return
+	}
+
+	if !exact && r.conf.Trim.Limit > 0 {
+		args.Limit = r.conf.Trim.Limit
+	}
+}
+
+// buildArgs resolves the per-part ID, stream, and trimming fields for part i
+// of msg into a fresh XAddArgs, ready to be passed to XAdd.
+func (r *redisStreamsWriter) buildArgs(i int, msg message.Batch, p *message.Part) *redis.XAddArgs {
+	values := map[string]interface{}{}
+	_ = r.metaFilter.Iter(p, func(k, v string) error {
+		values[k] = v
+		return nil
+	})
+	values[r.conf.BodyKey] = p.AsBytes()
+
+	args := &redis.XAddArgs{
+		ID:         r.id.String(i, msg),
+		Stream:     r.stream.String(i, msg),
+		NoMkStream: r.conf.NoMkStream,
+		Values:     values,
+	}
+	r.applyTrim(i, msg, args)
+	return args
+}
+
 func (r *redisStreamsWriter) WriteBatch(ctx context.Context, msg message.Batch) error {
 	r.connMut.RLock()
 	client := r.client
@@ -124,42 +193,33 @@ func (r *redisStreamsWriter) WriteBatch(ctx context.Context, msg message.Batch)
 		return component.ErrNotConnected
 	}
 
-	partToMap := func(p *message.Part) map[string]interface{} {
-		values := map[string]interface{}{}
-		_ = r.metaFilter.Iter(p, func(k, v string) error {
-			values[k] = v
-			return nil
-		})
-		values[r.conf.BodyKey] = p.AsBytes()
-		return values
-	}
-
 	if msg.Len() == 1 {
-		if err := client.XAdd(&redis.XAddArgs{
-			ID:           "*",
-			Stream:       r.stream.String(0, msg),
-			MaxLenApprox: r.conf.MaxLenApprox,
-			Values:       partToMap(msg.Get(0)),
-		}).Err(); err != nil {
-			_ = r.disconnect()
-			r.log.Errorf("Error from redis: %v\n", err)
-			return component.ErrNotConnected
+		args := r.buildArgs(0, msg, msg.Get(0))
+		if err := client.XAdd(ctx, args).Err(); err != nil {
+			var redisErr redis.Error
+			if !errors.As(err, &redisErr) {
+				// A network/transport failure rather than a RESP-level
+				// command error, so the connection needs to be re-established.
+				_ = r.disconnect()
+				r.log.Errorf("Error from redis: %v\n", err)
+				return component.ErrNotConnected
+			}
+			batchErr := ibatch.NewError(msg, err)
+			batchErr.Failed(0, err)
+			return batchErr
 		}
 		return nil
 	}
 
 	pipe := client.Pipeline()
 	_ = msg.Iter(func(i int, p *message.Part) error {
-		_ = pipe.XAdd(&redis.XAddArgs{
-			ID:           "*",
-			Stream:       r.stream.String(i, msg),
-			MaxLenApprox: r.conf.MaxLenApprox,
-			Values:       partToMap(p),
-		})
+		_ = pipe.XAdd(ctx, r.buildArgs(i, msg, p))
 		return nil
 	})
-	cmders, err := pipe.Exec()
-	if err != nil {
+	cmders, err := pipe.Exec(ctx)
+	if err != nil && cmders == nil {
+		// The pipeline never ran at all, so this is a transport failure
+		// rather than a per-command RESP error.
 		_ = r.disconnect()
 		r.log.Errorf("Error from redis: %v\n", err)
 		return component.ErrNotConnected
@@ -177,6 +237,13 @@ func (r *redisStreamsWriter) WriteBatch(ctx context.Context, msg message.Batch)
 	if batchErr != nil {
 		return batchErr
 	}
+	if err != nil {
+		// Exec reported a failure but no individual cmder carried it,
+		// which only happens on a genuine transport failure.
+		_ = r.disconnect()
+		r.log.Errorf("Error from redis: %v\n", err)
+		return component.ErrNotConnected
+	}
 	return nil
 }
 
@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/output"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+func newTestRedisStreamsWriter(t *testing.T, conf output.RedisStreamsConfig) *redisStreamsWriter {
+	t.Helper()
+	w, err := newRedisStreamsWriter(conf, mock.NewManager())
+	require.NoError(t, err)
+	return w
+}
+
+func TestRedisStreamsBuildArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*output.RedisStreamsConfig)
+		check  func(t *testing.T, args *redis.XAddArgs)
+	}{
+		{
+			name: "defaults to an auto-generated id and the legacy max length",
+			mutate: func(conf *output.RedisStreamsConfig) {
+				conf.MaxLenApprox = 1000
+			},
+			check: func(t *testing.T, args *redis.XAddArgs) {
+				assert.Equal(t, "*", args.ID)
+				assert.False(t, args.NoMkStream)
+				assert.EqualValues(t, 1000, args.MaxLen)
+				assert.True(t, args.Approx)
+			},
+		},
+		{
+			name: "explicit id and no_mk_stream are resolved",
+			mutate: func(conf *output.RedisStreamsConfig) {
+				conf.ID = "${! meta(\"id\") }"
+				conf.NoMkStream = true
+			},
+			check: func(t *testing.T, args *redis.XAddArgs) {
+				assert.Equal(t, "123-0", args.ID)
+				assert.True(t, args.NoMkStream)
+			},
+		},
+		{
+			name: "maxlen strategy in approx mode sets MaxLen and Approx",
+			mutate: func(conf *output.RedisStreamsConfig) {
+				conf.Trim.Strategy = "maxlen"
+				conf.Trim.Mode = "approx"
+				conf.Trim.Value = "500"
+				conf.Trim.Limit = 100
+			},
+			check: func(t *testing.T, args *redis.XAddArgs) {
+				assert.EqualValues(t, 500, args.MaxLen)
+				assert.True(t, args.Approx)
+				assert.EqualValues(t, 100, args.Limit)
+			},
+		},
+		{
+			name: "maxlen strategy in exact mode ignores limit",
+			mutate: func(conf *output.RedisStreamsConfig) {
+				conf.Trim.Strategy = "maxlen"
+				conf.Trim.Mode = "exact"
+				conf.Trim.Value = "500"
+				conf.Trim.Limit = 100
+			},
+			check: func(t *testing.T, args *redis.XAddArgs) {
+				assert.EqualValues(t, 500, args.MaxLen)
+				assert.False(t, args.Approx)
+				assert.Zero(t, args.Limit)
+			},
+		},
+		{
+			name: "minid strategy in approx mode sets MinID and Approx",
+			mutate: func(conf *output.RedisStreamsConfig) {
+				conf.Trim.Strategy = "minid"
+				conf.Trim.Mode = "approx"
+				conf.Trim.Value = "1700000000000-0"
+			},
+			check: func(t *testing.T, args *redis.XAddArgs) {
+				assert.Equal(t, "1700000000000-0", args.MinID)
+				assert.True(t, args.Approx)
+			},
+		},
+		{
+			name: "minid strategy in exact mode sets MinID without Approx",
+			mutate: func(conf *output.RedisStreamsConfig) {
+				conf.Trim.Strategy = "minid"
+				conf.Trim.Mode = "exact"
+				conf.Trim.Value = "1700000000000-0"
+			},
+			check: func(t *testing.T, args *redis.XAddArgs) {
+				assert.Equal(t, "1700000000000-0", args.MinID)
+				assert.False(t, args.Approx)
+			},
+		},
+		{
+			name: "an unparseable maxlen value skips trimming rather than trimming to zero",
+			mutate: func(conf *output.RedisStreamsConfig) {
+				conf.Trim.Strategy = "maxlen"
+				conf.Trim.Mode = "approx"
+				conf.Trim.Value = "not-a-number"
+			},
+			check: func(t *testing.T, args *redis.XAddArgs) {
+				assert.Zero(t, args.MaxLen)
+				assert.False(t, args.Approx)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := output.NewRedisStreamsConfig()
+			conf.Stream = "foostream"
+			conf.BodyKey = "body"
+			test.mutate(&conf)
+
+			w := newTestRedisStreamsWriter(t, conf)
+
+			part := message.NewPart([]byte("hello world"))
+			part.MetaSetMut("id", "123-0")
+			batch := message.Batch{part}
+
+			args := w.buildArgs(0, batch, part)
+			test.check(t, args)
+		})
+	}
+}